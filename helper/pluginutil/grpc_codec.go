@@ -0,0 +1,29 @@
+package pluginutil
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// GRPCCodec is a grpc.Codec that marshals with encoding/gob instead of
+// protobuf. Vault's gRPC plugin transport reuses gob, rather than
+// generating a parallel set of protobuf message types, for the
+// logical.Request/logical.Response pairs that already round-trip through
+// gob for the net/rpc transport. Both the client (PluginRunner.Run) and
+// server (logical/plugin.Serve) side of that transport need to agree on
+// it, so it lives here rather than in either package alone.
+type GRPCCodec struct{}
+
+func (GRPCCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GRPCCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (GRPCCodec) String() string { return "gob" }
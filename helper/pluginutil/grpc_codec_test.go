@@ -0,0 +1,27 @@
+package pluginutil
+
+import "testing"
+
+func TestGRPCCodec_RoundTrip(t *testing.T) {
+	type payload struct {
+		Name string
+		TTL  int
+	}
+
+	codec := GRPCCodec{}
+
+	in := &payload{Name: "mock", TTL: 60}
+	data, err := codec.Marshal(in)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var out payload
+	if err := codec.Unmarshal(data, &out); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if out != *in {
+		t.Fatalf("bad round trip: got %#v, want %#v", out, *in)
+	}
+}
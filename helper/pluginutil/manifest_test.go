@@ -0,0 +1,108 @@
+package pluginutil
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+)
+
+func testManifest(t *testing.T) (*PluginManifest, ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	m := &PluginManifest{
+		Entries: []PluginManifestEntry{
+			{OS: "linux", Arch: "amd64", Sha256: []byte{0x01, 0x02}},
+			{OS: "darwin", Arch: "arm64", Sha256: []byte{0x03, 0x04}},
+		},
+	}
+	m.Signature = ed25519.Sign(priv, m.CanonicalBytes())
+
+	return m, pub, priv
+}
+
+func TestPluginManifest_Verify(t *testing.T) {
+	m, pub, _ := testManifest(t)
+
+	if err := m.Verify([]ed25519.PublicKey{pub}); err != nil {
+		t.Fatalf("expected manifest to verify: %s", err)
+	}
+}
+
+func TestPluginManifest_Verify_noTrustedKeys(t *testing.T) {
+	m, _, _ := testManifest(t)
+
+	if err := m.Verify(nil); err == nil {
+		t.Fatal("expected error with no trusted keys")
+	}
+}
+
+func TestPluginManifest_Verify_unsigned(t *testing.T) {
+	_, pub, _ := testManifest(t)
+
+	m := &PluginManifest{Entries: []PluginManifestEntry{{OS: "linux", Arch: "amd64"}}}
+	if err := m.Verify([]ed25519.PublicKey{pub}); err == nil {
+		t.Fatal("expected error for unsigned manifest")
+	}
+}
+
+func TestPluginManifest_Verify_wrongKey(t *testing.T) {
+	m, _, _ := testManifest(t)
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := m.Verify([]ed25519.PublicKey{otherPub}); err == nil {
+		t.Fatal("expected error for wrong trusted key")
+	}
+}
+
+func TestPluginManifest_Verify_tamperedEntries(t *testing.T) {
+	m, pub, _ := testManifest(t)
+
+	m.Entries[0].Sha256 = []byte{0xff}
+
+	if err := m.Verify([]ed25519.PublicKey{pub}); err == nil {
+		t.Fatal("expected error for tampered entries")
+	}
+}
+
+func TestPluginManifest_CanonicalBytes_orderIndependent(t *testing.T) {
+	a := &PluginManifest{Entries: []PluginManifestEntry{
+		{OS: "linux", Arch: "amd64", Sha256: []byte{0x01}},
+		{OS: "darwin", Arch: "arm64", Sha256: []byte{0x02}},
+	}}
+	b := &PluginManifest{Entries: []PluginManifestEntry{
+		{OS: "darwin", Arch: "arm64", Sha256: []byte{0x02}},
+		{OS: "linux", Arch: "amd64", Sha256: []byte{0x01}},
+	}}
+
+	if string(a.CanonicalBytes()) != string(b.CanonicalBytes()) {
+		t.Fatalf("expected CanonicalBytes to be independent of Entries order")
+	}
+}
+
+func TestPluginManifest_entryFor(t *testing.T) {
+	m := &PluginManifest{Entries: []PluginManifestEntry{
+		{OS: "linux", Arch: "amd64", Sha256: []byte{0x01}},
+		{OS: "darwin", Arch: "arm64", Sha256: []byte{0x02}},
+	}}
+
+	entry, err := m.entryFor("linux", "amd64")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if entry.Sha256[0] != 0x01 {
+		t.Fatalf("bad entry: %#v", entry)
+	}
+
+	if _, err := m.entryFor("windows", "386"); err == nil {
+		t.Fatal("expected error for missing platform")
+	}
+}
@@ -1,16 +1,23 @@
 package pluginutil
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"os/exec"
+	"runtime"
+	"sort"
 	"time"
 
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/helper/wrapping"
 	log "github.com/mgutz/logxi/v1"
+	"google.golang.org/grpc"
 )
 
 // Looker defines the plugin Lookup function that looks into the plugin catalog
@@ -34,6 +41,27 @@ type LookRunnerUtil interface {
 	RunnerUtil
 }
 
+// ManifestTrustStore is implemented by RunnerUtil wrappers that can supply
+// the Ed25519 public keys trusted to sign a PluginManifest. It is checked
+// with a type assertion in Run rather than folded into RunnerUtil, so
+// existing RunnerUtil implementations (logical.SystemView adapters, test
+// doubles, etc.) don't all need a new method just to keep compiling. A
+// wrapper that doesn't implement it is treated as trusting no keys, so a
+// PluginRunner with a Manifest simply fails to verify rather than panicking.
+type ManifestTrustStore interface {
+	TrustedManifestKeys() []ed25519.PublicKey
+}
+
+// Protocol identifies the go-plugin wire protocol a plugin speaks. Plugins
+// registered before this field existed have an empty Protocol, which is
+// treated the same as ProtocolNetRPC.
+type Protocol string
+
+const (
+	ProtocolNetRPC Protocol = "net_rpc"
+	ProtocolGRPC   Protocol = "grpc"
+)
+
 // PluginRunner defines the metadata needed to run a plugin securely with
 // go-plugin.
 type PluginRunner struct {
@@ -43,6 +71,98 @@ type PluginRunner struct {
 	Sha256         []byte                      `json:"sha256" structs:"sha256"`
 	Builtin        bool                        `json:"builtin" structs:"builtin"`
 	BuiltinFactory func() (interface{}, error) `json:"-" structs:"-"`
+
+	// Protocol is the go-plugin transport the plugin process speaks. An
+	// empty value means ProtocolNetRPC, go-plugin's original gob-based
+	// transport. ProtocolGRPC requires the plugin to implement
+	// logical.Backend over gRPC instead of net/rpc. Its messages are
+	// encoded with GRPCCodec (gob), not protobuf, and every RPC is unary,
+	// so it carries neither non-Go plugin implementations nor streaming
+	// responses.
+	Protocol Protocol `json:"protocol" structs:"protocol"`
+
+	// Manifest, when set, takes precedence over Sha256: it pins a
+	// checksum per os/arch rather than a single binary, and carries a
+	// signature the catalog's trusted keys must verify before Run will
+	// execute the plugin. Sha256 remains for plugins registered before
+	// Manifest existed.
+	Manifest *PluginManifest `json:"manifest" structs:"manifest"`
+}
+
+// PluginManifestEntry pins the checksum of a single os/arch build of a
+// plugin binary.
+type PluginManifestEntry struct {
+	OS     string `json:"os" structs:"os"`
+	Arch   string `json:"arch" structs:"arch"`
+	Sha256 []byte `json:"sha256" structs:"sha256"`
+}
+
+// PluginManifest is a signed release manifest for a plugin: the set of
+// binaries built for each supported platform, plus a detached Ed25519
+// signature over Entries so a catalog entry can't be swapped for an
+// unsigned or tampered binary without detection.
+type PluginManifest struct {
+	Entries   []PluginManifestEntry `json:"entries" structs:"entries"`
+	Signature []byte                `json:"signature" structs:"signature"`
+}
+
+// entryFor returns the manifest entry matching goos/goarch, or an error if
+// the manifest has no build for that platform.
+func (m *PluginManifest) entryFor(goos, goarch string) (*PluginManifestEntry, error) {
+	for _, e := range m.Entries {
+		if e.OS == goos && e.Arch == goarch {
+			return &e, nil
+		}
+	}
+
+	return nil, fmt.Errorf("plugin manifest has no entry for %s/%s", goos, goarch)
+}
+
+// CanonicalBytes returns the exact byte sequence Signature is computed
+// over: Entries sorted by (OS, Arch), one per line, each formatted as
+// "<os> <arch> <lowercase hex sha256>\n". This manifest is signed outside
+// Vault by whoever cuts the plugin release, so the format is spelled out
+// explicitly here rather than left to depend on Go's struct-marshaling
+// behavior (field order, []byte-as-base64, map ordering, ...), none of
+// which a non-Go signing tool could be expected to reproduce byte for
+// byte.
+func (m *PluginManifest) CanonicalBytes() []byte {
+	entries := make([]PluginManifestEntry, len(m.Entries))
+	copy(entries, m.Entries)
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].OS != entries[j].OS {
+			return entries[i].OS < entries[j].OS
+		}
+		return entries[i].Arch < entries[j].Arch
+	})
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "%s %s %s\n", e.OS, e.Arch, hex.EncodeToString(e.Sha256))
+	}
+
+	return buf.Bytes()
+}
+
+// Verify checks Signature, over CanonicalBytes, against Entries using any
+// one of trustedKeys. It fails closed: an empty trustedKeys set, or a
+// manifest with no usable Signature, is treated as unverified.
+func (m *PluginManifest) Verify(trustedKeys []ed25519.PublicKey) error {
+	if len(trustedKeys) == 0 {
+		return errors.New("no trusted plugin manifest keys configured")
+	}
+	if len(m.Signature) == 0 {
+		return errors.New("plugin manifest is not signed")
+	}
+
+	signed := m.CanonicalBytes()
+	for _, key := range trustedKeys {
+		if ed25519.Verify(key, signed, m.Signature) {
+			return nil
+		}
+	}
+
+	return errors.New("plugin manifest signature does not verify against any trusted key")
 }
 
 // Run takes a wrapper RunnerUtil instance along with the go-plugin paramaters and
@@ -77,8 +197,26 @@ func (r *PluginRunner) Run(wrapper RunnerUtil, pluginMap map[string]plugin.Plugi
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", PluginMlockEnabled, "true"))
 	}
 
+	checksum := r.Sha256
+	if r.Manifest != nil {
+		var trustedKeys []ed25519.PublicKey
+		if ts, ok := wrapper.(ManifestTrustStore); ok {
+			trustedKeys = ts.TrustedManifestKeys()
+		}
+
+		if err := r.Manifest.Verify(trustedKeys); err != nil {
+			return nil, err
+		}
+
+		entry, err := r.Manifest.entryFor(runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			return nil, err
+		}
+		checksum = entry.Sha256
+	}
+
 	secureConfig := &plugin.SecureConfig{
-		Checksum: r.Sha256,
+		Checksum: checksum,
 		Hash:     sha256.New(),
 	}
 
@@ -95,13 +233,29 @@ func (r *PluginRunner) Run(wrapper RunnerUtil, pluginMap map[string]plugin.Plugi
 		clientTLSConfig = nil
 	}
 
+	// Default to net/rpc for plugins registered before Protocol existed.
+	allowedProtocols := []plugin.Protocol{plugin.ProtocolNetRPC}
+	var grpcServer func([]grpc.ServerOption) *grpc.Server
+	if r.Protocol == ProtocolGRPC {
+		allowedProtocols = []plugin.Protocol{plugin.ProtocolGRPC}
+		// The plugin's gRPC service is registered with GRPCCodec instead
+		// of the default protobuf codec, so the client-side server that
+		// accepts the plugin's broker callbacks needs to know about it
+		// too.
+		grpcServer = func(opts []grpc.ServerOption) *grpc.Server {
+			return grpc.NewServer(append(opts, grpc.CustomCodec(GRPCCodec{}))...)
+		}
+	}
+
 	client := plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig: hs,
-		Plugins:         pluginMap,
-		Cmd:             cmd,
-		TLSConfig:       clientTLSConfig,
-		SecureConfig:    secureConfig,
-		Logger:          namedLogger,
+		HandshakeConfig:  hs,
+		Plugins:          pluginMap,
+		Cmd:              cmd,
+		TLSConfig:        clientTLSConfig,
+		SecureConfig:     secureConfig,
+		Logger:           namedLogger,
+		AllowedProtocols: allowedProtocols,
+		GRPCServer:       grpcServer,
 	})
 
 	return client, nil
@@ -0,0 +1,192 @@
+package framework
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/logical"
+)
+
+// pathCapture is a single named regexp capture group, e.g. `(?P<name>...)`,
+// found in a Path's Pattern.
+type pathCapture struct {
+	Name  string
+	Start int // index of the capture's opening '('
+	End   int // index one past its matching ')'
+}
+
+// parsePathCaptures walks pattern and returns each named capture group in
+// order. Unlike a `\(\?P<(\w+)>[^)]*\)`-style regexp, it tracks paren depth
+// through the capture body, so it handles the nested groups this repo's own
+// Pattern helpers commonly produce (e.g. `(?P<name>\w(([\w-.]+)?\w)?)`)
+// instead of stopping at the first inner `)`.
+func parsePathCaptures(pattern string) []pathCapture {
+	const prefix = "(?P<"
+
+	var captures []pathCapture
+	for i := 0; i < len(pattern); i++ {
+		if !strings.HasPrefix(pattern[i:], prefix) {
+			continue
+		}
+
+		nameStart := i + len(prefix)
+		rel := strings.IndexByte(pattern[nameStart:], '>')
+		if rel < 0 {
+			continue
+		}
+		nameEnd := nameStart + rel
+
+		depth := 1
+		j := nameEnd + 1
+		for ; j < len(pattern) && depth > 0; j++ {
+			switch pattern[j] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+		}
+		if depth != 0 {
+			// Unbalanced; nothing further in pattern can be parsed
+			// reliably as a capture.
+			break
+		}
+
+		captures = append(captures, pathCapture{
+			Name:  pattern[nameStart:nameEnd],
+			Start: i,
+			End:   j,
+		})
+		i = j - 1
+	}
+
+	return captures
+}
+
+// PathSchema is the machine-readable description of a single Path. It
+// mirrors the information the help system already renders as text, but in a
+// form that can be marshaled to JSON and consumed by tools such as SDK
+// generators or Terraform providers.
+type PathSchema struct {
+	// Pattern is the Path's regular expression Pattern rewritten so that
+	// named captures read as URL template segments, e.g. `(?P<name>\w+)`
+	// becomes `{name}`.
+	Pattern string `json:"pattern"`
+
+	// Operations are the logical.Operations this Path has a callback
+	// registered for.
+	Operations []logical.Operation `json:"operations"`
+
+	// Fields describes every entry in the Path's Fields map, keyed by
+	// field name.
+	Fields map[string]*FieldSchemaDoc `json:"fields"`
+
+	// HelpSynopsis and HelpDescription are copied from the Path so that
+	// generated documentation can be built entirely from the schema.
+	HelpSynopsis    string `json:"description,omitempty"`
+	HelpDescription string `json:"long_description,omitempty"`
+}
+
+// FieldSchemaDoc is the schema for a single field on a Path. Its Type is
+// the field's framework FieldType name (FieldType.String()), not a JSON
+// Schema / OpenAPI type keyword.
+type FieldSchemaDoc struct {
+	Type        string      `json:"type"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+
+	// URL is true if this field is captured from the Path's Pattern
+	// rather than supplied in the request body.
+	URL bool `json:"url,omitempty"`
+}
+
+// Schema walks the Path's Pattern, Fields, and Callbacks and returns a
+// structured description suitable for JSON marshaling. Unlike helpCallback,
+// which renders a text template for human consumption, Schema is meant to
+// be consumed programmatically.
+func (p *Path) Schema() (*PathSchema, error) {
+	urlFields := pathURLFields(p.Pattern)
+
+	schema := &PathSchema{
+		Pattern:         pathPatternToURLTemplate(p.Pattern),
+		Fields:          make(map[string]*FieldSchemaDoc, len(p.Fields)),
+		HelpSynopsis:    p.HelpSynopsis,
+		HelpDescription: p.HelpDescription,
+	}
+
+	for name, field := range p.Fields {
+		schema.Fields[name] = &FieldSchemaDoc{
+			Type:        field.Type.String(),
+			Description: field.Description,
+			Default:     field.Default,
+			URL:         urlFields[name],
+		}
+	}
+
+	ops := make([]logical.Operation, 0, len(p.Callbacks))
+	for op := range p.Callbacks {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+	schema.Operations = ops
+
+	return schema, nil
+}
+
+// pathPatternToURLTemplate rewrites a Path's regular expression Pattern
+// into a URL template by replacing named captures, e.g. `(?P<name>\w+)`,
+// with their `{name}` template equivalent.
+func pathPatternToURLTemplate(pattern string) string {
+	captures := parsePathCaptures(pattern)
+
+	var b strings.Builder
+	prev := 0
+	for _, c := range captures {
+		b.WriteString(pattern[prev:c.Start])
+		b.WriteString("{")
+		b.WriteString(c.Name)
+		b.WriteString("}")
+		prev = c.End
+	}
+	b.WriteString(pattern[prev:])
+
+	return b.String()
+}
+
+// pathURLFields returns the set of field names that are captured from the
+// Pattern itself, as opposed to the request body.
+func pathURLFields(pattern string) map[string]bool {
+	captures := parsePathCaptures(pattern)
+
+	fields := make(map[string]bool, len(captures))
+	for _, c := range captures {
+		fields[c.Name] = true
+	}
+	return fields
+}
+
+// BackendSchema is a machine-readable description of every Path exposed by
+// a Backend, keyed by the Path's URL template.
+type BackendSchema struct {
+	Paths map[string]*PathSchema `json:"paths"`
+}
+
+// Schema walks every registered Path on the Backend and returns a
+// BackendSchema describing them all. It is the programmatic counterpart to
+// the Backend's generated help output, and is what backs the
+// sys/internal/schema/<mount> endpoint.
+func (b *Backend) Schema() (*BackendSchema, error) {
+	schema := &BackendSchema{
+		Paths: make(map[string]*PathSchema, len(b.Paths)),
+	}
+
+	for _, p := range b.Paths {
+		pathSchema, err := p.Schema()
+		if err != nil {
+			return nil, err
+		}
+		schema.Paths[pathSchema.Pattern] = pathSchema
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,75 @@
+package framework
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/logical"
+)
+
+func TestPathPatternToURLTemplate(t *testing.T) {
+	cases := map[string]string{
+		"roles/" + GenericNameRegex("name"):          "roles/{name}",
+		"roles/" + GenericNameRegex("name") + "/tidy": "roles/{name}/tidy",
+		"creds/(?P<role>[^/]+)":                       "creds/{role}",
+		"static":                                      "static",
+		"roles/(?P<name>\\w(([\\w-.]+)?\\w)?)":        "roles/{name}",
+		"(?P<a>\\w+)/nested/(?P<b>(foo|bar))":         "{a}/nested/{b}",
+	}
+
+	for pattern, want := range cases {
+		if got := pathPatternToURLTemplate(pattern); got != want {
+			t.Errorf("pathPatternToURLTemplate(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestPathURLFields(t *testing.T) {
+	fields := pathURLFields("roles/(?P<name>\\w(([\\w-.]+)?\\w)?)/(?P<suffix>.+)")
+	if !fields["name"] || !fields["suffix"] || len(fields) != 2 {
+		t.Fatalf("unexpected fields: %#v", fields)
+	}
+}
+
+func TestPathSchema(t *testing.T) {
+	p := &Path{
+		Pattern: "roles/(?P<name>\\w(([\\w-.]+)?\\w)?)",
+		Fields: map[string]*FieldSchema{
+			"name": &FieldSchema{
+				Type:        TypeString,
+				Description: "Name of the role.",
+			},
+			"ttl": &FieldSchema{
+				Type:        TypeDurationSecond,
+				Description: "TTL for the role.",
+				Default:     3600,
+			},
+		},
+		Callbacks: map[logical.Operation]OperationFunc{
+			logical.ReadOperation:   nil,
+			logical.UpdateOperation: nil,
+		},
+	}
+
+	schema, err := p.Schema()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if schema.Pattern != "roles/{name}" {
+		t.Fatalf("bad pattern: %s", schema.Pattern)
+	}
+
+	if !schema.Fields["name"].URL {
+		t.Fatalf("expected name field to be marked as a URL field")
+	}
+	if schema.Fields["ttl"].URL {
+		t.Fatalf("expected ttl field to not be marked as a URL field")
+	}
+	if schema.Fields["ttl"].Default != 3600 {
+		t.Fatalf("bad default: %v", schema.Fields["ttl"].Default)
+	}
+
+	if len(schema.Operations) != 2 {
+		t.Fatalf("bad operations: %#v", schema.Operations)
+	}
+}
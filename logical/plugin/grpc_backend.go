@@ -0,0 +1,358 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/vault/helper/pluginutil"
+	"github.com/hashicorp/vault/logical"
+	log "github.com/mgutz/logxi/v1"
+	"google.golang.org/grpc"
+)
+
+// backendServiceName is the gRPC service HandleRequest and friends are
+// registered and invoked under. There is no backend.proto/backend.pb.go:
+// logical.Request and logical.Response already have to round-trip through
+// gob for the existing net/rpc transport, so rather than hand-maintain a
+// parallel set of protobuf message types this transport reuses
+// pluginutil.GRPCCodec (gob) as the wire codec and builds the
+// grpc.ServiceDesc by hand, in the same shape protoc-gen-go would
+// otherwise generate.
+const backendServiceName = "vault.plugin.Backend"
+
+// GRPCBackendPlugin implements go-plugin's plugin.GRPCPlugin so a
+// logical.Backend can be served over go-plugin's gRPC transport instead of
+// the legacy net/rpc backendPlugin.
+//
+// This transport is RPC scaffolding, not yet a drop-in replacement for the
+// net/rpc backendPlugin: Config.System/Config.StorageView are not bridged
+// across the broker (see setupArgs below), so any backend whose request
+// handling calls back into SystemView or StorageView — which is most
+// non-trivial secrets/auth plugins — will get a nil System()/StorageView()
+// on the plugin side. It's usable today for backends whose Paths are
+// self-contained; broker-backed System/StorageView proxying is required
+// before this can serve as a general replacement.
+type GRPCBackendPlugin struct {
+	Factory logical.Factory
+}
+
+func (p *GRPCBackendPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	s.RegisterService(&backendServiceDesc, &grpcBackendServer{factory: p.Factory, broker: broker})
+	return nil
+}
+
+func (p *GRPCBackendPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &grpcBackendClient{conn: c, broker: broker}, nil
+}
+
+// backendHandler adapts a (*grpcBackendServer, context.Context, args) call
+// into the grpc.MethodDesc.Handler shape, decoding args with newArgs and
+// running any configured server interceptor. Every RPC below is unary, so
+// they all share this one wrapper instead of repeating its boilerplate.
+func backendHandler(method string, newArgs func() interface{}, call func(*grpcBackendServer, context.Context, interface{}) (interface{}, error)) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		in := newArgs()
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+
+		s := srv.(*grpcBackendServer)
+		if interceptor == nil {
+			return call(s, ctx, in)
+		}
+
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + backendServiceName + "/" + method}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return call(s, ctx, req)
+		}
+		return interceptor(ctx, in, info, handler)
+	}
+}
+
+// backendServer is the interface backendServiceDesc registers RPCs
+// against. grpc.Server.RegisterService requires HandlerType to be an
+// interface value (it does reflect.TypeOf(HandlerType).Elem() and asserts
+// the registered implementation satisfies it) — passing the concrete
+// *grpcBackendServer type there panics as soon as any gRPC-mode plugin
+// starts, since reflect.Type.Implements requires an interface Kind().
+// protoc-gen-go output always defines this interface for exactly this
+// reason; we do the same by hand here.
+type backendServer interface {
+	handleRequest(ctx context.Context, args *handleRequestArgs) (*handleRequestReply, error)
+	handleExistenceCheck(ctx context.Context, args *handleExistenceCheckArgs) (*handleExistenceCheckReply, error)
+	specialPaths(ctx context.Context) (*specialPathsReply, error)
+	setup(ctx context.Context, args *setupArgs) (*setupReply, error)
+	initialize(ctx context.Context) (*errReply, error)
+	invalidateKey(ctx context.Context, args *invalidateKeyArgs) (*noArgs, error)
+	cleanup(ctx context.Context) (*noArgs, error)
+	backendType(ctx context.Context) (*typeReply, error)
+}
+
+var backendServiceDesc = grpc.ServiceDesc{
+	ServiceName: backendServiceName,
+	HandlerType: (*backendServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "HandleRequest",
+			Handler: backendHandler("HandleRequest", func() interface{} { return new(handleRequestArgs) },
+				func(s *grpcBackendServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return s.handleRequest(ctx, in.(*handleRequestArgs))
+				}),
+		},
+		{
+			MethodName: "HandleExistenceCheck",
+			Handler: backendHandler("HandleExistenceCheck", func() interface{} { return new(handleExistenceCheckArgs) },
+				func(s *grpcBackendServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return s.handleExistenceCheck(ctx, in.(*handleExistenceCheckArgs))
+				}),
+		},
+		{
+			MethodName: "SpecialPaths",
+			Handler: backendHandler("SpecialPaths", func() interface{} { return new(noArgs) },
+				func(s *grpcBackendServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return s.specialPaths(ctx)
+				}),
+		},
+		{
+			MethodName: "Setup",
+			Handler: backendHandler("Setup", func() interface{} { return new(setupArgs) },
+				func(s *grpcBackendServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return s.setup(ctx, in.(*setupArgs))
+				}),
+		},
+		{
+			MethodName: "Initialize",
+			Handler: backendHandler("Initialize", func() interface{} { return new(noArgs) },
+				func(s *grpcBackendServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return s.initialize(ctx)
+				}),
+		},
+		{
+			MethodName: "InvalidateKey",
+			Handler: backendHandler("InvalidateKey", func() interface{} { return new(invalidateKeyArgs) },
+				func(s *grpcBackendServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return s.invalidateKey(ctx, in.(*invalidateKeyArgs))
+				}),
+		},
+		{
+			MethodName: "Cleanup",
+			Handler: backendHandler("Cleanup", func() interface{} { return new(noArgs) },
+				func(s *grpcBackendServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return s.cleanup(ctx)
+				}),
+		},
+		{
+			MethodName: "Type",
+			Handler: backendHandler("Type", func() interface{} { return new(noArgs) },
+				func(s *grpcBackendServer, ctx context.Context, in interface{}) (interface{}, error) {
+					return s.backendType(ctx)
+				}),
+		},
+	},
+}
+
+type noArgs struct{}
+
+type errReply struct {
+	Err string
+}
+
+func (r *errReply) error() error {
+	if r == nil || r.Err == "" {
+		return nil
+	}
+	return errors.New(r.Err)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+type handleRequestArgs struct {
+	Request *logical.Request
+}
+
+type handleRequestReply struct {
+	Response *logical.Response
+	ErrReply errReply
+}
+
+type handleExistenceCheckArgs struct {
+	Request *logical.Request
+}
+
+type handleExistenceCheckReply struct {
+	CheckFound bool
+	Exists     bool
+	ErrReply   errReply
+}
+
+type specialPathsReply struct {
+	Paths *logical.Paths
+}
+
+// setupArgs carries everything the plugin side needs to construct its
+// logical.Backend. Config.System and Config.StorageView are not
+// gob-serializable themselves; bridging them across the broker so the
+// plugin can call back into core (for leases, storage, etc.) is the same
+// broker-based proxy pattern the existing net/rpc backendPlugin transport
+// already uses, and is left as follow-up work for the gRPC transport
+// rather than duplicated here. Until it lands, Config.System/StorageView
+// are nil on the plugin side, so requests that call back into core (as
+// opposed to ones handled purely within the Path's own logic) are not yet
+// supported over gRPC.
+type setupArgs struct {
+	Config map[string]string
+}
+
+type setupReply struct {
+	ErrReply errReply
+}
+
+type invalidateKeyArgs struct {
+	Key string
+}
+
+type typeReply struct {
+	Type logical.BackendType
+}
+
+// grpcBackendServer runs in the plugin process. It owns the real
+// logical.Backend, built from Factory on Setup, and forwards each RPC to
+// it.
+type grpcBackendServer struct {
+	factory logical.Factory
+	broker  *plugin.GRPCBroker
+
+	backend logical.Backend
+}
+
+func (s *grpcBackendServer) handleRequest(ctx context.Context, args *handleRequestArgs) (*handleRequestReply, error) {
+	resp, err := s.backend.HandleRequest(args.Request)
+	return &handleRequestReply{Response: resp, ErrReply: errReply{Err: errString(err)}}, nil
+}
+
+func (s *grpcBackendServer) handleExistenceCheck(ctx context.Context, args *handleExistenceCheckArgs) (*handleExistenceCheckReply, error) {
+	checkFound, exists, err := s.backend.HandleExistenceCheck(args.Request)
+	return &handleExistenceCheckReply{CheckFound: checkFound, Exists: exists, ErrReply: errReply{Err: errString(err)}}, nil
+}
+
+func (s *grpcBackendServer) specialPaths(ctx context.Context) (*specialPathsReply, error) {
+	return &specialPathsReply{Paths: s.backend.SpecialPaths()}, nil
+}
+
+func (s *grpcBackendServer) setup(ctx context.Context, args *setupArgs) (*setupReply, error) {
+	backend, err := s.factory(&logical.BackendConfig{Config: args.Config})
+	if err != nil {
+		return &setupReply{ErrReply: errReply{Err: errString(err)}}, nil
+	}
+	s.backend = backend
+	return &setupReply{}, nil
+}
+
+func (s *grpcBackendServer) initialize(ctx context.Context) (*errReply, error) {
+	return &errReply{Err: errString(s.backend.Initialize())}, nil
+}
+
+func (s *grpcBackendServer) invalidateKey(ctx context.Context, args *invalidateKeyArgs) (*noArgs, error) {
+	s.backend.InvalidateKey(args.Key)
+	return &noArgs{}, nil
+}
+
+func (s *grpcBackendServer) cleanup(ctx context.Context) (*noArgs, error) {
+	s.backend.Cleanup()
+	return &noArgs{}, nil
+}
+
+func (s *grpcBackendServer) backendType(ctx context.Context) (*typeReply, error) {
+	return &typeReply{Type: s.backend.Type()}, nil
+}
+
+// grpcBackendClient runs in Vault core and implements logical.Backend by
+// invoking the RPCs registered in backendServiceDesc against the plugin
+// process.
+type grpcBackendClient struct {
+	conn   *grpc.ClientConn
+	broker *plugin.GRPCBroker
+
+	// system and logger are cached from the arguments Setup is called
+	// with: they already live in the core process, so returning them
+	// from System()/Logger() needs no RPC.
+	system logical.SystemView
+	logger log.Logger
+}
+
+func (c *grpcBackendClient) invoke(method string, args, reply interface{}) error {
+	return c.conn.Invoke(context.Background(), "/"+backendServiceName+"/"+method, args, reply, grpc.CallCustomCodec(pluginutil.GRPCCodec{}))
+}
+
+func (c *grpcBackendClient) HandleRequest(req *logical.Request) (*logical.Response, error) {
+	reply := new(handleRequestReply)
+	if err := c.invoke("HandleRequest", &handleRequestArgs{Request: req}, reply); err != nil {
+		return nil, err
+	}
+	return reply.Response, reply.ErrReply.error()
+}
+
+func (c *grpcBackendClient) HandleExistenceCheck(req *logical.Request) (bool, bool, error) {
+	reply := new(handleExistenceCheckReply)
+	if err := c.invoke("HandleExistenceCheck", &handleExistenceCheckArgs{Request: req}, reply); err != nil {
+		return false, false, err
+	}
+	return reply.CheckFound, reply.Exists, reply.ErrReply.error()
+}
+
+func (c *grpcBackendClient) SpecialPaths() *logical.Paths {
+	reply := new(specialPathsReply)
+	if err := c.invoke("SpecialPaths", &noArgs{}, reply); err != nil {
+		return nil
+	}
+	return reply.Paths
+}
+
+func (c *grpcBackendClient) System() logical.SystemView {
+	return c.system
+}
+
+func (c *grpcBackendClient) Logger() log.Logger {
+	return c.logger
+}
+
+func (c *grpcBackendClient) Setup(config *logical.BackendConfig) error {
+	c.system = config.System
+	c.logger = config.Logger
+
+	reply := new(setupReply)
+	if err := c.invoke("Setup", &setupArgs{Config: config.Config}, reply); err != nil {
+		return err
+	}
+	return reply.ErrReply.error()
+}
+
+func (c *grpcBackendClient) Initialize() error {
+	reply := new(errReply)
+	if err := c.invoke("Initialize", &noArgs{}, reply); err != nil {
+		return err
+	}
+	return reply.error()
+}
+
+func (c *grpcBackendClient) InvalidateKey(key string) {
+	c.invoke("InvalidateKey", &invalidateKeyArgs{Key: key}, new(noArgs))
+}
+
+func (c *grpcBackendClient) Cleanup() {
+	c.invoke("Cleanup", &noArgs{}, new(noArgs))
+}
+
+func (c *grpcBackendClient) Type() logical.BackendType {
+	reply := new(typeReply)
+	if err := c.invoke("Type", &noArgs{}, reply); err != nil {
+		return 0
+	}
+	return reply.Type
+}
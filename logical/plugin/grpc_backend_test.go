@@ -0,0 +1,140 @@
+package plugin
+
+import (
+	"net"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/pluginutil"
+	"github.com/hashicorp/vault/logical"
+	log "github.com/mgutz/logxi/v1"
+	"google.golang.org/grpc"
+)
+
+// stubBackend is a minimal logical.Backend used to drive backendServiceDesc
+// end to end without a real plugin process.
+type stubBackend struct {
+	initialized bool
+	invalidated string
+	cleanedUp   bool
+}
+
+func (b *stubBackend) HandleRequest(req *logical.Request) (*logical.Response, error) {
+	return &logical.Response{Data: map[string]interface{}{"path": req.Path}}, nil
+}
+
+func (b *stubBackend) HandleExistenceCheck(req *logical.Request) (bool, bool, error) {
+	return true, req.Path == "exists", nil
+}
+
+func (b *stubBackend) SpecialPaths() *logical.Paths {
+	return &logical.Paths{Unauthenticated: []string{"login"}}
+}
+
+func (b *stubBackend) System() logical.SystemView { return nil }
+func (b *stubBackend) Logger() log.Logger         { return nil }
+
+func (b *stubBackend) Setup(config *logical.BackendConfig) error {
+	return nil
+}
+
+func (b *stubBackend) Initialize() error {
+	b.initialized = true
+	return nil
+}
+
+func (b *stubBackend) InvalidateKey(key string) {
+	b.invalidated = key
+}
+
+func (b *stubBackend) Cleanup() {
+	b.cleanedUp = true
+}
+
+func (b *stubBackend) Type() logical.BackendType {
+	return logical.TypeLogical
+}
+
+// startTestBackendServer registers backendServiceDesc on an in-process
+// grpc.Server listening on a loopback port, returning a grpcBackendClient
+// dialed against it and a cleanup func. This is the test the HandlerType
+// panic (grpc.Server.RegisterService requires an interface, not a concrete
+// struct pointer, in ServiceDesc.HandlerType) would have caught immediately.
+func startTestBackendServer(t *testing.T, backend *stubBackend) (*grpcBackendClient, func()) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	server := grpc.NewServer(grpc.CustomCodec(pluginutil.GRPCCodec{}))
+	server.RegisterService(&backendServiceDesc, &grpcBackendServer{
+		factory: func(*logical.BackendConfig) (logical.Backend, error) { return backend, nil },
+	})
+	go server.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		server.Stop()
+		t.Fatalf("err: %s", err)
+	}
+
+	client := &grpcBackendClient{conn: conn}
+
+	return client, func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestGRPCBackend_endToEnd(t *testing.T) {
+	backend := &stubBackend{}
+	client, cleanup := startTestBackendServer(t, backend)
+	defer cleanup()
+
+	if err := client.Setup(&logical.BackendConfig{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	resp, err := client.HandleRequest(&logical.Request{Path: "foo"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if resp.Data["path"] != "foo" {
+		t.Fatalf("bad response: %#v", resp)
+	}
+
+	checkFound, exists, err := client.HandleExistenceCheck(&logical.Request{Path: "exists"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !checkFound || !exists {
+		t.Fatalf("bad existence check: checkFound=%v exists=%v", checkFound, exists)
+	}
+
+	paths := client.SpecialPaths()
+	if len(paths.Unauthenticated) != 1 || paths.Unauthenticated[0] != "login" {
+		t.Fatalf("bad special paths: %#v", paths)
+	}
+
+	if err := client.Initialize(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !backend.initialized {
+		t.Fatalf("expected backend to be initialized")
+	}
+
+	client.InvalidateKey("some-key")
+	if backend.invalidated != "some-key" {
+		t.Fatalf("expected InvalidateKey to forward key, got %q", backend.invalidated)
+	}
+
+	if typ := client.Type(); typ != logical.TypeLogical {
+		t.Fatalf("bad type: %v", typ)
+	}
+
+	client.Cleanup()
+	if !backend.cleanedUp {
+		t.Fatalf("expected backend to be cleaned up")
+	}
+}
@@ -0,0 +1,65 @@
+package plugin
+
+import (
+	"crypto/tls"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/vault/helper/pluginutil"
+	"github.com/hashicorp/vault/logical"
+	"google.golang.org/grpc"
+)
+
+var handshakeConfig = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "VAULT_BACKEND_PLUGIN",
+	MagicCookieValue: "6669da05-b1c8-4f49-97d9-c8e5bed98e20",
+}
+
+// ServeOpts are the options for serving a logical.Backend as a plugin
+// binary's main().
+type ServeOpts struct {
+	BackendFactoryFunc logical.Factory
+	TLSProviderFunc    func() (*tls.Config, error)
+
+	// Protocol selects the go-plugin transport the backend is served
+	// over. An empty value means pluginutil.ProtocolNetRPC, matching
+	// plugins written before Protocol existed. Set to
+	// pluginutil.ProtocolGRPC to serve the backend as a
+	// GRPCBackendPlugin instead of the net/rpc backendPlugin. Its
+	// messages are encoded with pluginutil.GRPCCodec (gob), so the other
+	// end must speak gob over gRPC, not protobuf.
+	Protocol pluginutil.Protocol
+}
+
+// Serve starts the plugin backend process, blocking until go-plugin shuts
+// it down. It should be called from a plugin binary's main().
+func Serve(opts *ServeOpts) error {
+	tlsConfig, err := opts.TLSProviderFunc()
+	if err != nil {
+		return err
+	}
+
+	serveConfig := &plugin.ServeConfig{
+		HandshakeConfig: handshakeConfig,
+		TLSProvider:     func() (*tls.Config, error) { return tlsConfig, nil },
+	}
+
+	if opts.Protocol == pluginutil.ProtocolGRPC {
+		// backendServiceDesc's methods are registered with
+		// pluginutil.GRPCCodec, not the default protobuf codec, so the
+		// server needs to know about it too.
+		serveConfig.GRPCServer = func(opts []grpc.ServerOption) *grpc.Server {
+			return grpc.NewServer(append(opts, grpc.CustomCodec(pluginutil.GRPCCodec{}))...)
+		}
+		serveConfig.Plugins = map[string]plugin.Plugin{
+			"backend": &GRPCBackendPlugin{Factory: opts.BackendFactoryFunc},
+		}
+	} else {
+		serveConfig.Plugins = map[string]plugin.Plugin{
+			"backend": &backendPlugin{Factory: opts.BackendFactoryFunc},
+		}
+	}
+
+	plugin.Serve(serveConfig)
+	return nil
+}
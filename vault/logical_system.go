@@ -0,0 +1,31 @@
+package vault
+
+import (
+	"github.com/hashicorp/vault/logical/framework"
+	log "github.com/mgutz/logxi/v1"
+)
+
+// SystemBackend holds the framework.Backend mounted at the "sys/" prefix;
+// it does not itself implement logical.Backend. Only the subset of its Paths touched by this series (schema
+// export, plugin catalog registration) is assembled here; sys/'s other
+// paths (mounts, policies, audit, leases, ...) are unaffected by these
+// changes and aren't duplicated into this file.
+type SystemBackend struct {
+	Core    *Core
+	Backend *framework.Backend
+}
+
+// NewSystemBackend constructs the sys/ backend with the schema export and
+// plugin catalog Paths; it registers no other Paths.
+func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
+	b := &SystemBackend{Core: core}
+
+	b.Backend = &framework.Backend{
+		Help: "The system backend exposes Vault's internal operations, such as mounting backends, managing policies, and viewing audit logs.",
+	}
+
+	b.Backend.Paths = append(b.Backend.Paths, b.schemaPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.pluginCatalogPaths()...)
+
+	return b
+}
@@ -0,0 +1,175 @@
+package vault
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/helper/pluginutil"
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+// pluginCatalogManifestFields extends the sys/plugins/catalog/:type/:name
+// registration path so a plugin can be registered with a signed
+// PluginManifest instead of (or alongside, for backwards compatibility) a
+// bare sha256. Entries are expressed as a list of "os,arch,sha256" strings,
+// hex-encoded to match the sibling sha256 field, so they round-trip through
+// the same flat Fields map the CLI already posts form-encoded plugin
+// registrations through.
+var pluginCatalogManifestFields = map[string]*framework.FieldSchema{
+	"manifest_entries": &framework.FieldSchema{
+		Type:        framework.TypeStringSlice,
+		Description: "Per-platform checksums, each formatted as \"os,arch,hex-sha256\". Takes precedence over sha256 when set.",
+	},
+	"manifest_signature": &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Base64-encoded Ed25519 signature over the manifest's canonical form (one \"os arch hex-sha256\\n\" line per entry, sorted by os then arch), verified against the catalog's trusted manifest keys.",
+	},
+}
+
+// decodePluginManifest builds a pluginutil.PluginManifest from the
+// manifest_entries/manifest_signature fields of a catalog registration
+// request. It returns a nil manifest, not an error, when manifest_entries
+// is absent so existing bare-sha256 registrations keep working.
+func decodePluginManifest(data *framework.FieldData) (*pluginutil.PluginManifest, error) {
+	rawEntries := data.Get("manifest_entries").([]string)
+	if len(rawEntries) == 0 {
+		return nil, nil
+	}
+
+	manifest := &pluginutil.PluginManifest{
+		Entries: make([]pluginutil.PluginManifestEntry, len(rawEntries)),
+	}
+	for i, raw := range rawEntries {
+		parts := strings.Split(raw, ",")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid manifest_entries entry %q: expected \"os,arch,hex-sha256\"", raw)
+		}
+
+		sha, err := hex.DecodeString(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid sha256 in manifest_entries entry %q: %s", raw, err)
+		}
+
+		manifest.Entries[i] = pluginutil.PluginManifestEntry{
+			OS:     parts[0],
+			Arch:   parts[1],
+			Sha256: sha,
+		}
+	}
+
+	if sig, ok := data.GetOk("manifest_signature"); ok {
+		decoded, err := base64.StdEncoding.DecodeString(sig.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest_signature: %s", err)
+		}
+		manifest.Signature = decoded
+	}
+
+	return manifest, nil
+}
+
+// validatePluginManifest decodes and, if present, verifies a manifest
+// against trustedKeys, rejecting the registration outright when the
+// signature doesn't check out.
+func validatePluginManifest(data *framework.FieldData, trustedKeys []ed25519.PublicKey) (*pluginutil.PluginManifest, error) {
+	manifest, err := decodePluginManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, nil
+	}
+
+	if err := manifest.Verify(trustedKeys); err != nil {
+		return nil, fmt.Errorf("refusing to register plugin: %s", err)
+	}
+
+	return manifest, nil
+}
+
+// TrustedManifestKeys returns the Ed25519 public keys the core's plugin
+// catalog trusts to sign a PluginManifest. It satisfies
+// pluginutil.ManifestTrustStore so Core can be used directly as the
+// wrapper PluginRunner.Run verifies signed manifests against.
+func (c *Core) TrustedManifestKeys() []ed25519.PublicKey {
+	return c.pluginCatalog.trustedManifestKeys
+}
+
+// pluginCatalogPaths returns the sys/plugins/catalog/:type/:name path,
+// extended with pluginCatalogManifestFields so a registration can carry a
+// signed PluginManifest instead of a bare sha256.
+func (b *SystemBackend) pluginCatalogPaths() []*framework.Path {
+	fields := map[string]*framework.FieldSchema{
+		"name": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "Name of the plugin.",
+		},
+		"type": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "Type of the plugin; one of \"auth\", \"secret\", or \"database\".",
+		},
+		"sha256": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "Hex-encoded SHA256 of the plugin binary. Ignored when manifest_entries is set.",
+		},
+		"command": &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: "Command, relative to the plugin directory, used to execute the plugin.",
+		},
+		"args": &framework.FieldSchema{
+			Type:        framework.TypeStringSlice,
+			Description: "Arguments to pass to command.",
+		},
+	}
+	for name, schema := range pluginCatalogManifestFields {
+		fields[name] = schema
+	}
+
+	return []*framework.Path{
+		{
+			Pattern: "plugins/catalog/(?P<type>[^/]+)/(?P<name>.+)",
+
+			Fields: fields,
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: b.handleRegisterPlugin,
+			},
+
+			HelpSynopsis:    "Register a new plugin, or updates an existing one, with the catalog.",
+			HelpDescription: "Accepts either a bare sha256 or a signed PluginManifest (manifest_entries/manifest_signature) covering multiple os/arch builds; a manifest whose signature doesn't verify against the catalog's trusted keys is rejected.",
+		},
+	}
+}
+
+func (b *SystemBackend) handleRegisterPlugin(
+	req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	manifest, err := validatePluginManifest(data, b.Core.TrustedManifestKeys())
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	runner := &pluginutil.PluginRunner{
+		Name:     data.Get("name").(string),
+		Command:  data.Get("command").(string),
+		Args:     data.Get("args").([]string),
+		Manifest: manifest,
+	}
+
+	if manifest == nil {
+		sha, err := hex.DecodeString(data.Get("sha256").(string))
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("invalid sha256: %s", err)), nil
+		}
+		runner.Sha256 = sha
+	}
+
+	if err := b.Core.pluginCatalog.Set(runner, data.Get("type").(string)); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
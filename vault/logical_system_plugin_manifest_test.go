@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+func TestDecodePluginManifest_absent(t *testing.T) {
+	data := &framework.FieldData{
+		Raw:    map[string]interface{}{},
+		Schema: pluginCatalogManifestFields,
+	}
+
+	manifest, err := decodePluginManifest(data)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if manifest != nil {
+		t.Fatalf("expected nil manifest, got %#v", manifest)
+	}
+}
+
+func TestDecodePluginManifest_entries(t *testing.T) {
+	data := &framework.FieldData{
+		Raw: map[string]interface{}{
+			"manifest_entries": []string{
+				"linux,amd64," + hex.EncodeToString([]byte{0x01}),
+				"darwin,arm64," + hex.EncodeToString([]byte{0x02}),
+			},
+		},
+		Schema: pluginCatalogManifestFields,
+	}
+
+	manifest, err := decodePluginManifest(data)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(manifest.Entries) != 2 {
+		t.Fatalf("bad entries: %#v", manifest.Entries)
+	}
+	if manifest.Entries[0].OS != "linux" || manifest.Entries[0].Arch != "amd64" {
+		t.Fatalf("bad entry: %#v", manifest.Entries[0])
+	}
+}
+
+func TestDecodePluginManifest_malformedEntry(t *testing.T) {
+	data := &framework.FieldData{
+		Raw: map[string]interface{}{
+			"manifest_entries": []string{"linux-only-one-field"},
+		},
+		Schema: pluginCatalogManifestFields,
+	}
+
+	if _, err := decodePluginManifest(data); err == nil {
+		t.Fatal("expected error for malformed manifest_entries")
+	}
+}
+
+func TestValidatePluginManifest_rejectsBadSignature(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	data := &framework.FieldData{
+		Raw: map[string]interface{}{
+			"manifest_entries":   []string{"linux,amd64," + hex.EncodeToString([]byte{0x01})},
+			"manifest_signature": base64.StdEncoding.EncodeToString([]byte("not-a-real-signature-not-a-real-signature-64by")),
+		},
+		Schema: pluginCatalogManifestFields,
+	}
+
+	if _, err := validatePluginManifest(data, []ed25519.PublicKey{pub}); err == nil {
+		t.Fatal("expected error for unverifiable manifest signature")
+	}
+}
+
+func TestNewSystemBackend_registersPluginCatalogPath(t *testing.T) {
+	b := NewSystemBackend(nil, nil)
+
+	found := false
+	for _, p := range b.Backend.Paths {
+		if p.Pattern == "plugins/catalog/(?P<type>[^/]+)/(?P<name>.+)" {
+			found = true
+			if _, ok := p.Fields["manifest_entries"]; !ok {
+				t.Fatalf("expected manifest_entries field on catalog path")
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected sys/plugins/catalog path to be registered")
+	}
+}
@@ -0,0 +1,60 @@
+package vault
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+// schemaPaths returns the sys/internal/schema/<mount> path, which exposes a
+// machine-readable description of a mount's API surface. It is built from
+// the same Fields/Pattern metadata that backs the text help output, so it
+// always stays in sync with what the backend actually accepts.
+func (b *SystemBackend) schemaPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "internal/schema/(?P<mount>.+)",
+
+			Fields: map[string]*framework.FieldSchema{
+				"mount": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The mount path to return the schema for.",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation: b.handleSchema,
+			},
+
+			HelpSynopsis:    "Return a machine-readable schema for a mount.",
+			HelpDescription: "Walks the mount's registered paths and returns a structured description of its fields, operations, and URL templates, suitable for SDK or documentation generators.",
+		},
+	}
+}
+
+func (b *SystemBackend) handleSchema(
+	req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	mount := data.Get("mount").(string)
+
+	backend := b.Core.router.MatchingBackend(mount)
+	if backend == nil {
+		return logical.ErrorResponse(fmt.Sprintf("no backend mounted at %q", mount)), nil
+	}
+
+	fb, ok := backend.(*framework.Backend)
+	if !ok {
+		return logical.ErrorResponse(fmt.Sprintf("mount %q does not support schema generation", mount)), nil
+	}
+
+	schema, err := fb.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"schema": schema,
+		},
+	}, nil
+}
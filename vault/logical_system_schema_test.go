@@ -0,0 +1,35 @@
+package vault
+
+import "testing"
+
+func TestSystemBackend_schemaPaths(t *testing.T) {
+	b := &SystemBackend{}
+	paths := b.schemaPaths()
+
+	if len(paths) != 1 {
+		t.Fatalf("expected 1 path, got %d", len(paths))
+	}
+
+	p := paths[0]
+	if p.Pattern != "internal/schema/(?P<mount>.+)" {
+		t.Fatalf("bad pattern: %s", p.Pattern)
+	}
+
+	if _, ok := p.Fields["mount"]; !ok {
+		t.Fatalf("expected a mount field")
+	}
+}
+
+func TestNewSystemBackend_registersSchemaPath(t *testing.T) {
+	b := NewSystemBackend(nil, nil)
+
+	found := false
+	for _, p := range b.Backend.Paths {
+		if p.Pattern == "internal/schema/(?P<mount>.+)" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected sys/internal/schema path to be registered, got paths: %#v", b.Backend.Paths)
+	}
+}